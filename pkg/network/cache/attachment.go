@@ -0,0 +1,43 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package cache
+
+// StaticAddress is a single static IPv4/IPv6 address hint, together with the routes that must be
+// installed alongside it.
+type StaticAddress struct {
+	Address string
+	Routes  []string
+}
+
+// AttachmentRequest carries the guest-visible identity a caller wants pinned for a given network
+// attachment: the requested pod-side interface name, MAC, and any static addresses. It is persisted
+// next to the network's PodIfaceState so that a later Reload can honor it without the caller having to
+// resupply it.
+type AttachmentRequest struct {
+	Ifname    string
+	MAC       string
+	Addresses []StaticAddress
+}
+
+// IsEmpty reports whether the request carries no hints at all, i.e. the attachment should be left
+// entirely to discovery.
+func (r AttachmentRequest) IsEmpty() bool {
+	return r.Ifname == "" && r.MAC == "" && len(r.Addresses) == 0
+}
@@ -0,0 +1,65 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package cache
+
+const (
+	// SchemaVersionUnversioned identifies entries written before the cache adopted a versioned
+	// envelope, when a network's on-disk entry was a bare PodIfaceState with no attributes at all.
+	// It is also the zero value of CacheEnvelope.SchemaVersion, so envelopes decoded from that legacy
+	// format naturally report this version.
+	SchemaVersionUnversioned = 0
+
+	// SchemaVersionCurrent is the envelope version this binary writes. Bump it, and extend
+	// MigrateEnvelope, whenever a new field is added to CacheEnvelope that older readers must
+	// tolerate missing.
+	SchemaVersionCurrent = 1
+)
+
+// CacheEnvelope is the on-disk representation of everything ConfigState persists for a single
+// network: its preparation state, plus the attributes introduced to support Reload and pinned
+// pod-interface/static-IP attachment requests. Every on-disk write goes through this envelope so that
+// adding a field here is a schema change MigrateEnvelope knows how to reconcile, rather than a silent
+// reinterpretation of old bytes.
+type CacheEnvelope struct {
+	SchemaVersion     int
+	State             PodIfaceState
+	Attributes        PodIfaceAttributes
+	AttachmentRequest AttachmentRequest
+}
+
+// MigrateEnvelope normalizes env to SchemaVersionCurrent, filling in whatever defaults a given past
+// version implies. Envelopes at or above SchemaVersionCurrent are returned unchanged: a version newer
+// than this binary knows about may carry fields it cannot interpret, and the safest forward-compatible
+// behavior is to leave them alone rather than risk truncating data a newer launcher wrote.
+func MigrateEnvelope(env CacheEnvelope) CacheEnvelope {
+	if env.SchemaVersion >= SchemaVersionCurrent {
+		return env
+	}
+
+	switch env.SchemaVersion {
+	case SchemaVersionUnversioned:
+		// The unversioned format only ever carried a bare state; Attributes and AttachmentRequest
+		// default to their zero values, which correctly express "nothing was ever recorded".
+		env.SchemaVersion = SchemaVersionCurrent
+	default:
+		env.SchemaVersion = SchemaVersionCurrent
+	}
+	return env
+}
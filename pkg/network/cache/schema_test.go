@@ -0,0 +1,86 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrateEnvelopeUnversionedGetsCurrentSchema(t *testing.T) {
+	env := CacheEnvelope{State: PodIfaceNetworkPreparationFinished}
+	if env.SchemaVersion != SchemaVersionUnversioned {
+		t.Fatalf("expected the zero value to be SchemaVersionUnversioned, got %d", env.SchemaVersion)
+	}
+
+	migrated := MigrateEnvelope(env)
+
+	if migrated.SchemaVersion != SchemaVersionCurrent {
+		t.Errorf("SchemaVersion = %d, want %d", migrated.SchemaVersion, SchemaVersionCurrent)
+	}
+	if migrated.State != PodIfaceNetworkPreparationFinished {
+		t.Errorf("State = %v, want %v", migrated.State, PodIfaceNetworkPreparationFinished)
+	}
+	if !migrated.AttachmentRequest.IsEmpty() {
+		t.Errorf("AttachmentRequest = %+v, want empty", migrated.AttachmentRequest)
+	}
+}
+
+func TestMigrateEnvelopeCurrentSchemaIsUnchanged(t *testing.T) {
+	env := CacheEnvelope{
+		SchemaVersion: SchemaVersionCurrent,
+		State:         PodIfaceNetworkPreparationFinished,
+		Attributes:    PodIfaceAttributes{MAC: "02:00:00:00:00:01"},
+	}
+
+	migrated := MigrateEnvelope(env)
+
+	if !reflect.DeepEqual(migrated, env) {
+		t.Errorf("MigrateEnvelope(%+v) = %+v, want unchanged", env, migrated)
+	}
+}
+
+// TestMigrateEnvelopeForwardCompat documents and locks in the forward-compatibility contract: an
+// envelope written by a future binary, carrying a SchemaVersion this code doesn't know about yet, must
+// be returned unchanged rather than rejected or silently downgraded. A future schema version is only
+// ever greater than SchemaVersionCurrent, so this is exercised with SchemaVersionCurrent+1.
+func TestMigrateEnvelopeForwardCompat(t *testing.T) {
+	future := CacheEnvelope{
+		SchemaVersion: SchemaVersionCurrent + 1,
+		State:         PodIfaceNetworkPreparationReloading,
+		Attributes:    PodIfaceAttributes{MAC: "02:00:00:00:00:02", IPs: []string{"10.0.0.5/24"}},
+		AttachmentRequest: AttachmentRequest{
+			Ifname: "eth7",
+			MAC:    "02:00:00:00:00:03",
+		},
+	}
+
+	migrated := MigrateEnvelope(future)
+
+	if migrated.SchemaVersion != future.SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d (unknown future versions must be left alone)", migrated.SchemaVersion, future.SchemaVersion)
+	}
+	if migrated.State != future.State {
+		t.Errorf("State = %v, want %v", migrated.State, future.State)
+	}
+	if !reflect.DeepEqual(migrated.AttachmentRequest, future.AttachmentRequest) {
+		t.Errorf("AttachmentRequest = %+v, want %+v", migrated.AttachmentRequest, future.AttachmentRequest)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package cache
+
+// PodIfaceState tracks the network preparation progress of a single pod
+// interface, as persisted by the launcher's on-disk cache.
+type PodIfaceState int
+
+const (
+	// PodIfaceNetworkPreparationPending marks a network that has not been
+	// discovered or configured yet.
+	PodIfaceNetworkPreparationPending PodIfaceState = iota
+	// PodIfaceNetworkPreparationStarted marks a network whose configuration
+	// step has begun. Under normal operation this state is terminal: the
+	// configuration step must not be re-entered once it has started.
+	PodIfaceNetworkPreparationStarted
+	// PodIfaceNetworkPreparationFinished marks a network whose configuration
+	// step completed successfully.
+	PodIfaceNetworkPreparationFinished
+	// PodIfaceNetworkPreparationReloading marks a network whose configuration
+	// is being torn down and re-applied in place (e.g. after a launcher
+	// restart or a CNI reconfiguration), as opposed to being configured for
+	// the first time. Unlike PodIfaceNetworkPreparationStarted, this state is
+	// expected to be re-entered and resolves back to
+	// PodIfaceNetworkPreparationFinished once the reload completes.
+	PodIfaceNetworkPreparationReloading
+)
+
+// PodIfaceAttributes captures the guest-visible L2/L3 identity of a pod
+// interface: the values that must survive a teardown/recreate cycle so the
+// guest does not observe an address change.
+type PodIfaceAttributes struct {
+	MAC    string
+	IPs    []string
+	Routes []string
+}
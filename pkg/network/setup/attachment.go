@@ -0,0 +1,50 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package network
+
+import (
+	"fmt"
+
+	"kubevirt.io/kubevirt/pkg/network/namescheme"
+
+	"kubevirt.io/kubevirt/pkg/network/cache"
+)
+
+// SetAttachmentRequest validates and persists req as the attachment request for networkName. It must
+// be called before Run/ConnectNetwork discovers that network, so the requested pod-interface name,
+// MAC, and static addresses are available to thread into discoverFunc.
+func (c *ConfigState) SetAttachmentRequest(networkName string, req cache.AttachmentRequest) error {
+	if req.Ifname != "" && namescheme.OrdinalSecondaryInterfaceName(req.Ifname) {
+		return fmt.Errorf(
+			"requested pod interface name %q for network %s collides with the ordinal naming scheme",
+			req.Ifname, networkName,
+		)
+	}
+
+	envelope, err := c.readEnvelope(networkName)
+	if err != nil {
+		return fmt.Errorf("failed to read cache envelope for %s: %w", networkName, err)
+	}
+	envelope.AttachmentRequest = req
+	if err := c.cache.WriteEnvelope(networkName, envelope); err != nil {
+		return fmt.Errorf("failed to persist attachment request for %s: %w", networkName, err)
+	}
+	return nil
+}
@@ -34,10 +34,21 @@ import (
 	neterrors "kubevirt.io/kubevirt/pkg/network/errors"
 )
 
+// configStateCacheRUD is deliberately narrow: every field of a network's persisted state
+// (PodIfaceState, AttachmentRequest, PodIfaceAttributes) lives together in a single cache.CacheEnvelope,
+// and ReadEnvelope/WriteEnvelope are the only read/write primitives. There is no separate bare-state or
+// bare-attachment-request accessor, so there is no ambiguity about whether writing one field clobbers
+// another already on disk: every mutation in this package goes through readEnvelope (which migrates
+// lazily) and writes back the whole envelope with only the field it cares about changed.
 type configStateCacheRUD interface {
-	Read(networkName string) (cache.PodIfaceState, error)
-	Write(networkName string, state cache.PodIfaceState) error
 	Delete(networkName string) error
+
+	// ReadEnvelope returns the full versioned cache envelope persisted for networkName, decoded as-is
+	// from disk (i.e. without migrating it). A network that was never written reads back with
+	// SchemaVersion set to cache.SchemaVersionUnversioned and a zero State.
+	ReadEnvelope(networkName string) (cache.CacheEnvelope, error)
+	// WriteEnvelope persists envelope verbatim for networkName.
+	WriteEnvelope(networkName string, envelope cache.CacheEnvelope) error
 }
 
 type ConfigState struct {
@@ -45,6 +56,12 @@ type ConfigState struct {
 	ns                    NSExecutor
 	launcherPid           int
 	podIfaceNameByNetwork map[string]string
+
+	// RollbackHook, when set, is invoked (in reverse order) on the NIC that failed its configuration
+	// step and on each NIC configured before it, so a partial multi-network attach can be undone rather
+	// than left half-configured. When nil, a configuration failure keeps its historical behavior of
+	// escalating straight to a critical, unrecoverable error.
+	RollbackHook func(*podNIC) error
 }
 
 func NewConfigState(configStateCache configStateCacheRUD, ns NSExecutor, launcherPid int) ConfigState {
@@ -55,28 +72,82 @@ func NewConfigStateWithPodIfaceMap(configStateCache configStateCacheRUD, ns NSEx
 	return ConfigState{cache: configStateCache, ns: ns, launcherPid: launcherPid, podIfaceNameByNetwork: podIfaceNameByNetwork}
 }
 
+// readEnvelope reads networkName's cache envelope and lazily migrates it to cache.SchemaVersionCurrent
+// before returning it, persisting the migrated envelope back to the cache. Every read of a network's
+// state or attachment request goes through this helper, so a pod whose cache predates the envelope (or
+// predates a later field added to it) is migrated the first time it is touched, regardless of whether
+// MigrateCache was ever called for it.
+func (c *ConfigState) readEnvelope(networkName string) (cache.CacheEnvelope, error) {
+	envelope, err := c.cache.ReadEnvelope(networkName)
+	if err != nil {
+		return cache.CacheEnvelope{}, err
+	}
+
+	migrated := cache.MigrateEnvelope(envelope)
+	if migrated.SchemaVersion == envelope.SchemaVersion {
+		return envelope, nil
+	}
+
+	if werr := c.cache.WriteEnvelope(networkName, migrated); werr != nil {
+		return cache.CacheEnvelope{}, fmt.Errorf("failed to persist migrated cache envelope for %s: %w", networkName, werr)
+	}
+	return migrated, nil
+}
+
+// writeState reads networkName's current envelope, replaces only its State, and writes the whole
+// envelope back, so the AttachmentRequest and Attributes already on disk for that network are
+// preserved across every state transition (Pending/Started/Finished/Reloading) instead of depending on
+// the backing store to merge a bare state write on its own.
+func (c *ConfigState) writeState(networkName string, state cache.PodIfaceState) error {
+	envelope, err := c.readEnvelope(networkName)
+	if err != nil {
+		return err
+	}
+	envelope.State = state
+	return c.cache.WriteEnvelope(networkName, envelope)
+}
+
 // Run passes through the state machine flow, executing the following steps:
 // - PreRun processes the nics and potentially updates and filters them (e.g. filter-out networks marked for removal).
 // - Discover the current pod network configuration status and persist some of it for future use.
 // - Configure the pod network.
 //
+// attachmentRequestsByNetwork optionally pins the requested pod-side interface name, MAC, and static
+// addresses for a network, keyed by its vmiSpecNetwork.Name, e.g. derived from the VMI spec. For a
+// network with no entry (or an empty one), nothing is persisted here: discovery receives whatever
+// attachment request is already stored for it, or an empty one if none was ever set.
+//
 // The discovery step can be executed repeatedly with no limitation.
 // The configuration step is allowed to run only once. Any attempt to run it again will cause a critical error.
-func (c *ConfigState) Run(nics []podNIC, preRunFunc func([]podNIC) ([]podNIC, error), discoverFunc func(*podNIC) error, configFunc func(*podNIC) error) error {
+func (c *ConfigState) Run(
+	nics []podNIC,
+	attachmentRequestsByNetwork map[string]cache.AttachmentRequest,
+	preRunFunc func([]podNIC) ([]podNIC, error),
+	discoverFunc func(*podNIC, cache.AttachmentRequest) error,
+	configFunc func(*podNIC) error,
+) error {
 	var pendingNICs []podNIC
 	for _, nic := range nics {
-		state, err := c.cache.Read(nic.vmiSpecNetwork.Name)
+		envelope, err := c.readEnvelope(nic.vmiSpecNetwork.Name)
 		if err != nil {
 			return err
 		}
 
-		switch state {
+		switch envelope.State {
 		case cache.PodIfaceNetworkPreparationPending:
 			pendingNICs = append(pendingNICs, nic)
 		case cache.PodIfaceNetworkPreparationStarted:
 			return neterrors.CreateCriticalNetworkError(
 				fmt.Errorf("network %s preparation cannot be restarted", nic.vmiSpecNetwork.Name),
 			)
+		case cache.PodIfaceNetworkPreparationReloading:
+			// A prior Reload of this network never reached PodIfaceNetworkPreparationFinished, so its
+			// pod-side configuration is in an unknown, possibly torn-down state. Surface this as a
+			// critical error instead of silently dropping the network from pendingNICs, which would
+			// otherwise let Run report success for a network that isn't actually configured.
+			return neterrors.CreateCriticalNetworkError(
+				fmt.Errorf("network %s is stuck mid-reload and requires manual intervention", nic.vmiSpecNetwork.Name),
+			)
 		}
 	}
 	nics = pendingNICs
@@ -99,20 +170,33 @@ func (c *ConfigState) Run(nics []podNIC, preRunFunc func([]podNIC) ([]podNIC, er
 				c.podIfaceNameByNetwork[nic.vmiSpecNetwork.Name] = nic.podInterfaceName
 			}
 		}
+		for _, nic := range nics {
+			req, exist := attachmentRequestsByNetwork[nic.vmiSpecNetwork.Name]
+			if !exist || req.IsEmpty() {
+				continue
+			}
+			if serr := c.SetAttachmentRequest(nic.vmiSpecNetwork.Name, req); serr != nil {
+				return serr
+			}
+		}
 		return c.plug(nics, discoverFunc, configFunc)
 	})
 	return err
 }
 
-func (c *ConfigState) plug(nics []podNIC, discoverFunc func(*podNIC) error, configFunc func(*podNIC) error) error {
+func (c *ConfigState) plug(nics []podNIC, discoverFunc func(*podNIC, cache.AttachmentRequest) error, configFunc func(*podNIC) error) error {
 	for i := range nics {
-		if ferr := discoverFunc(&nics[i]); ferr != nil {
+		envelope, rerr := c.readEnvelope(nics[i].vmiSpecNetwork.Name)
+		if rerr != nil {
+			return rerr
+		}
+		if ferr := discoverFunc(&nics[i], envelope.AttachmentRequest); ferr != nil {
 			return ferr
 		}
 	}
 
 	for _, nic := range nics {
-		if werr := c.cache.Write(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationStarted); werr != nil {
+		if werr := c.writeState(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationStarted); werr != nil {
 			return fmt.Errorf("failed to mark configuration as started for %s: %w", nic.vmiSpecNetwork.Name, werr)
 		}
 	}
@@ -122,12 +206,183 @@ func (c *ConfigState) plug(nics []podNIC, discoverFunc func(*podNIC) error, conf
 	for i := range nics {
 		if ferr := configFunc(&nics[i]); ferr != nil {
 			log.Log.Reason(ferr).Errorf("failed to configure pod network: %s", nics[i].vmiSpecNetwork.Name)
-			return neterrors.CreateCriticalNetworkError(ferr)
+			if c.RollbackHook == nil {
+				return neterrors.CreateCriticalNetworkError(ferr)
+			}
+			// nics[:i] were fully configured and must be unwound via RollbackHook; nics[i] itself
+			// just failed configFunc but may have partially applied its changes, so it is unwound the
+			// same way. nics[i+1:] never reached configFunc at all, so they only need their cache
+			// entry reset, not an unwind call.
+			if rerr := c.rollback(nics[:i+1]); rerr != nil {
+				return neterrors.CreateCriticalNetworkError(
+					fmt.Errorf("failed to roll back partially configured networks after %v: %w", ferr, rerr),
+				)
+			}
+			if rerr := c.resetPending(nics[i+1:]); rerr != nil {
+				return neterrors.CreateCriticalNetworkError(
+					fmt.Errorf("failed to reset untried networks after %v: %w", ferr, rerr),
+				)
+			}
+			return ferr
+		}
+	}
+
+	for _, nic := range nics {
+		if werr := c.writeState(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationFinished); werr != nil {
+			return neterrors.CreateCriticalNetworkError(
+				fmt.Errorf("failed to mark configuration as finished for %s: %w", nic.vmiSpecNetwork.Name, werr),
+			)
+		}
+	}
+	return nil
+}
+
+// rollback unwinds configuredNICs in reverse configuration order via RollbackHook and resets each
+// one's cache entry back to PodIfaceNetworkPreparationPending, so a subsequent Run can retry them from
+// scratch. It is only called when a NIC's configFunc fails; it must not be called once any NIC has
+// reached PodIfaceNetworkPreparationFinished.
+func (c *ConfigState) rollback(configuredNICs []podNIC) error {
+	for i := len(configuredNICs) - 1; i >= 0; i-- {
+		nic := configuredNICs[i]
+		if uerr := c.RollbackHook(&nic); uerr != nil {
+			return fmt.Errorf("failed to unwind configuration for %s: %w", nic.vmiSpecNetwork.Name, uerr)
+		}
+		if werr := c.writeState(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationPending); werr != nil {
+			return fmt.Errorf("failed to reset cache state for %s: %w", nic.vmiSpecNetwork.Name, werr)
+		}
+	}
+	return nil
+}
+
+// resetPending resets the cache entry of each NIC in untriedNICs back to
+// PodIfaceNetworkPreparationPending. It is used for NICs that were marked
+// PodIfaceNetworkPreparationStarted alongside a batch but never reached configFunc because an earlier
+// NIC in the same batch failed first, so there is nothing to unwind via RollbackHook, only the cache
+// entry to reset.
+func (c *ConfigState) resetPending(untriedNICs []podNIC) error {
+	for _, nic := range untriedNICs {
+		if werr := c.writeState(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationPending); werr != nil {
+			return fmt.Errorf("failed to reset cache state for %s: %w", nic.vmiSpecNetwork.Name, werr)
+		}
+	}
+	return nil
+}
+
+// resetFinished resets the cache entry of each NIC in untouchedNICs back to
+// PodIfaceNetworkPreparationFinished. It is used during a failed Reload for NICs whose teardownFunc was
+// never invoked, so their existing configuration is still intact and they need not be left stuck at
+// PodIfaceNetworkPreparationReloading.
+func (c *ConfigState) resetFinished(untouchedNICs []podNIC) error {
+	for _, nic := range untouchedNICs {
+		if werr := c.writeState(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationFinished); werr != nil {
+			return fmt.Errorf("failed to reset cache state for %s: %w", nic.vmiSpecNetwork.Name, werr)
+		}
+	}
+	return nil
+}
+
+// Reload re-runs network preparation for NICs that are already finished, tearing down their current
+// configuration and re-applying it in place. It is meant for cases where the pod network must be
+// repaired without a live migration, e.g. a launcher restart or a CNI reconfiguration: the caller
+// supplies the same discoverFunc/configFunc pair used by Run, plus a teardownFunc to undo the current
+// configuration and a snapshotFunc/restoreFunc pair to carry the MAC and IP/route attributes across the
+// teardown so the guest sees no address change.
+func (c *ConfigState) Reload(
+	nics []podNIC,
+	snapshotFunc func(*podNIC) (cache.PodIfaceAttributes, error),
+	teardownFunc func(*podNIC) error,
+	discoverFunc func(*podNIC, cache.AttachmentRequest) error,
+	configFunc func(*podNIC) error,
+	restoreFunc func(*podNIC, cache.PodIfaceAttributes) error,
+) error {
+	var reloadableNICs []podNIC
+	for _, nic := range nics {
+		envelope, err := c.readEnvelope(nic.vmiSpecNetwork.Name)
+		if err != nil {
+			return err
+		}
+
+		if envelope.State == cache.PodIfaceNetworkPreparationFinished {
+			reloadableNICs = append(reloadableNICs, nic)
+		}
+	}
+	nics = reloadableNICs
+
+	if len(nics) == 0 {
+		return nil
+	}
+
+	return c.ns.Do(func() error {
+		return c.reload(nics, snapshotFunc, teardownFunc, discoverFunc, configFunc, restoreFunc)
+	})
+}
+
+func (c *ConfigState) reload(
+	nics []podNIC,
+	snapshotFunc func(*podNIC) (cache.PodIfaceAttributes, error),
+	teardownFunc func(*podNIC) error,
+	discoverFunc func(*podNIC, cache.AttachmentRequest) error,
+	configFunc func(*podNIC) error,
+	restoreFunc func(*podNIC, cache.PodIfaceAttributes) error,
+) error {
+	attributesByNetwork := map[string]cache.PodIfaceAttributes{}
+	for i := range nics {
+		attrs, serr := snapshotFunc(&nics[i])
+		if serr != nil {
+			return fmt.Errorf("failed to snapshot attributes for %s before reload: %w", nics[i].vmiSpecNetwork.Name, serr)
+		}
+		attributesByNetwork[nics[i].vmiSpecNetwork.Name] = attrs
+	}
+
+	for _, nic := range nics {
+		if werr := c.writeState(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationReloading); werr != nil {
+			return fmt.Errorf("failed to mark configuration as reloading for %s: %w", nic.vmiSpecNetwork.Name, werr)
+		}
+	}
+
+	for i := range nics {
+		if terr := teardownFunc(&nics[i]); terr != nil {
+			// nics[i+1:] were never handed to teardownFunc, so their old configuration is still
+			// intact: they can safely revert to Finished instead of being left stuck at Reloading.
+			// nics[0:i+1] are in an unknown state (torn down, or a failed partial teardown) and stay
+			// Reloading, which Run now surfaces as a critical, visible error rather than silently
+			// dropping them.
+			if rerr := c.resetFinished(nics[i+1:]); rerr != nil {
+				return neterrors.CreateCriticalNetworkError(
+					fmt.Errorf("failed to revert untouched networks to finished after %v: %w", terr, rerr),
+				)
+			}
+			return neterrors.CreateCriticalNetworkError(terr)
+		}
+	}
+
+	for i := range nics {
+		envelope, rerr := c.readEnvelope(nics[i].vmiSpecNetwork.Name)
+		if rerr != nil {
+			return neterrors.CreateCriticalNetworkError(rerr)
+		}
+		if derr := discoverFunc(&nics[i], envelope.AttachmentRequest); derr != nil {
+			return neterrors.CreateCriticalNetworkError(derr)
+		}
+	}
+
+	for i := range nics {
+		if cerr := configFunc(&nics[i]); cerr != nil {
+			log.Log.Reason(cerr).Errorf("failed to reconfigure pod network: %s", nics[i].vmiSpecNetwork.Name)
+			return neterrors.CreateCriticalNetworkError(cerr)
+		}
+	}
+
+	for i := range nics {
+		if rerr := restoreFunc(&nics[i], attributesByNetwork[nics[i].vmiSpecNetwork.Name]); rerr != nil {
+			return neterrors.CreateCriticalNetworkError(
+				fmt.Errorf("failed to restore MAC/IP attributes for %s after reload: %w", nics[i].vmiSpecNetwork.Name, rerr),
+			)
 		}
 	}
 
 	for _, nic := range nics {
-		if werr := c.cache.Write(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationFinished); werr != nil {
+		if werr := c.writeState(nic.vmiSpecNetwork.Name, cache.PodIfaceNetworkPreparationFinished); werr != nil {
 			return neterrors.CreateCriticalNetworkError(
 				fmt.Errorf("failed to mark configuration as finished for %s: %w", nic.vmiSpecNetwork.Name, werr),
 			)
@@ -179,11 +434,11 @@ func (c *ConfigState) networksToUnplug(specInterfaces []v1.Interface) ([]string,
 				continue
 			}
 
-			state, err := c.cache.Read(specIface.Name)
+			envelope, err := c.readEnvelope(specIface.Name)
 			if err != nil {
 				return nil, err
 			}
-			if state != cache.PodIfaceNetworkPreparationPending {
+			if envelope.State != cache.PodIfaceNetworkPreparationPending {
 				networksToUnplug = append(networksToUnplug, specIface.Name)
 			}
 		}
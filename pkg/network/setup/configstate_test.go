@@ -0,0 +1,177 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package network
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "kubevirt.io/api/core/v1"
+
+	"kubevirt.io/kubevirt/pkg/network/cache"
+)
+
+// fakeConfigStateCache is an in-memory stand-in for configStateCacheRUD, keyed by network name. A
+// network that was never written reads back as a zero-valued envelope, matching ReadEnvelope's
+// documented contract.
+type fakeConfigStateCache struct {
+	envelopes map[string]cache.CacheEnvelope
+}
+
+func newFakeConfigStateCache() *fakeConfigStateCache {
+	return &fakeConfigStateCache{envelopes: map[string]cache.CacheEnvelope{}}
+}
+
+func (f *fakeConfigStateCache) Delete(networkName string) error {
+	delete(f.envelopes, networkName)
+	return nil
+}
+
+func (f *fakeConfigStateCache) ReadEnvelope(networkName string) (cache.CacheEnvelope, error) {
+	return f.envelopes[networkName], nil
+}
+
+func (f *fakeConfigStateCache) WriteEnvelope(networkName string, envelope cache.CacheEnvelope) error {
+	f.envelopes[networkName] = envelope
+	return nil
+}
+
+// fakeNSExecutor runs its argument inline, with no actual namespace switch, which is all these tests
+// need: a sequencing point to call into, not real netns isolation.
+type fakeNSExecutor struct{}
+
+func (fakeNSExecutor) Do(f func() error) error { return f() }
+
+func newTestNIC(name string) podNIC {
+	return podNIC{vmiSpecNetwork: &v1.Network{Name: name}, podInterfaceName: name + "-iface"}
+}
+
+func TestRollbackResetsFailedAndUntriedNICsToPending(t *testing.T) {
+	fakeCache := newFakeConfigStateCache()
+	nics := []podNIC{newTestNIC("a"), newTestNIC("b"), newTestNIC("c")}
+
+	var rolledBack []string
+	configState := ConfigState{
+		cache: fakeCache,
+		ns:    fakeNSExecutor{},
+		RollbackHook: func(nic *podNIC) error {
+			rolledBack = append(rolledBack, nic.vmiSpecNetwork.Name)
+			return nil
+		},
+	}
+
+	discoverFunc := func(*podNIC, cache.AttachmentRequest) error { return nil }
+	configFunc := func(nic *podNIC) error {
+		if nic.vmiSpecNetwork.Name == "b" {
+			return fmt.Errorf("configuration failed for b")
+		}
+		return nil
+	}
+
+	err := configState.plug(nics, discoverFunc, configFunc)
+	if err == nil {
+		t.Fatal("expected plug to fail when configFunc fails for one NIC")
+	}
+
+	// "a" was fully configured before "b" failed, so it must be unwound via RollbackHook; "b" itself
+	// just failed configFunc and is unwound the same way; "c" never reached configFunc at all.
+	wantRolledBack := []string{"b", "a"}
+	if len(rolledBack) != len(wantRolledBack) {
+		t.Fatalf("RollbackHook calls = %v, want %v", rolledBack, wantRolledBack)
+	}
+	for i, name := range wantRolledBack {
+		if rolledBack[i] != name {
+			t.Errorf("RollbackHook call %d = %s, want %s", i, rolledBack[i], name)
+		}
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		envelope := fakeCache.envelopes[name]
+		if envelope.State != cache.PodIfaceNetworkPreparationPending {
+			t.Errorf("network %s state = %v, want Pending", name, envelope.State)
+		}
+	}
+}
+
+func TestReloadTeardownFailureLeavesUntouchedFinishedAndTornDownReloading(t *testing.T) {
+	fakeCache := newFakeConfigStateCache()
+	nics := []podNIC{newTestNIC("a"), newTestNIC("b"), newTestNIC("c")}
+	for _, nic := range nics {
+		fakeCache.envelopes[nic.vmiSpecNetwork.Name] = cache.CacheEnvelope{
+			SchemaVersion: cache.SchemaVersionCurrent,
+			State:         cache.PodIfaceNetworkPreparationFinished,
+		}
+	}
+
+	configState := ConfigState{cache: fakeCache, ns: fakeNSExecutor{}}
+
+	snapshotFunc := func(*podNIC) (cache.PodIfaceAttributes, error) { return cache.PodIfaceAttributes{}, nil }
+	teardownFunc := func(nic *podNIC) error {
+		if nic.vmiSpecNetwork.Name == "b" {
+			return fmt.Errorf("teardown failed for b")
+		}
+		return nil
+	}
+	discoverFunc := func(*podNIC, cache.AttachmentRequest) error { return nil }
+	configFunc := func(*podNIC) error { return nil }
+	restoreFunc := func(*podNIC, cache.PodIfaceAttributes) error { return nil }
+
+	err := configState.reload(nics, snapshotFunc, teardownFunc, discoverFunc, configFunc, restoreFunc)
+	if err == nil {
+		t.Fatal("expected reload to fail when teardownFunc fails for one NIC")
+	}
+
+	// "a" was already torn down before "b" failed, and "b" itself failed mid-teardown: both are in an
+	// unknown state and must stay Reloading. "c" was never handed to teardownFunc, so it reverts to
+	// Finished instead of being left stuck.
+	wantStates := map[string]cache.PodIfaceState{
+		"a": cache.PodIfaceNetworkPreparationReloading,
+		"b": cache.PodIfaceNetworkPreparationReloading,
+		"c": cache.PodIfaceNetworkPreparationFinished,
+	}
+	for name, want := range wantStates {
+		if got := fakeCache.envelopes[name].State; got != want {
+			t.Errorf("network %s state = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRunRejectsReloadingNetwork(t *testing.T) {
+	fakeCache := newFakeConfigStateCache()
+	fakeCache.envelopes["a"] = cache.CacheEnvelope{
+		SchemaVersion: cache.SchemaVersionCurrent,
+		State:         cache.PodIfaceNetworkPreparationReloading,
+	}
+
+	configState := ConfigState{cache: fakeCache, ns: fakeNSExecutor{}}
+
+	preRunFunc := func(nics []podNIC) ([]podNIC, error) { return nics, nil }
+	discoverCalled := false
+	discoverFunc := func(*podNIC, cache.AttachmentRequest) error { discoverCalled = true; return nil }
+	configFunc := func(*podNIC) error { return nil }
+
+	err := configState.Run([]podNIC{newTestNIC("a")}, nil, preRunFunc, discoverFunc, configFunc)
+	if err == nil {
+		t.Fatal("expected Run to reject a network stuck in Reloading")
+	}
+	if discoverCalled {
+		t.Error("discoverFunc must not be called for a network Run rejected")
+	}
+}
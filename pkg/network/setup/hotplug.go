@@ -0,0 +1,100 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package network
+
+import (
+	"fmt"
+
+	v1 "kubevirt.io/api/core/v1"
+
+	"kubevirt.io/kubevirt/pkg/network/cache"
+	neterrors "kubevirt.io/kubevirt/pkg/network/errors"
+)
+
+// ConnectNetwork attaches a single named network to the pod, outside of the Run spec-reconciliation
+// flow. It is meant for imperative hotplug callers, such as virt-handler answering a subresource
+// request, that know about one network at a time rather than a full VMI spec. req pins the requested
+// pod-side interface name, MAC, and static addresses across discovery; it is validated and persisted
+// the same way a Run-driven attachment request is. ConnectNetwork serializes with any in-flight
+// Run/Reload/DisconnectNetwork call through the same NSExecutor.
+func (c *ConfigState) ConnectNetwork(name string, req cache.AttachmentRequest, discoverFunc func(*podNIC, cache.AttachmentRequest) error, configFunc func(*podNIC) error) error {
+	nic := podNIC{
+		vmiSpecNetwork:   &v1.Network{Name: name},
+		podInterfaceName: req.Ifname,
+	}
+
+	return c.ns.Do(func() error {
+		envelope, err := c.readEnvelope(name)
+		if err != nil {
+			return err
+		}
+		if envelope.State == cache.PodIfaceNetworkPreparationReloading {
+			// Mirror Run's handling of a stuck mid-reload network: its pod-side configuration is in an
+			// unknown, possibly torn-down state, so silently reporting success (as the early return
+			// below does for Started and Finished networks) would hide a network that isn't actually
+			// connected.
+			return neterrors.CreateCriticalNetworkError(
+				fmt.Errorf("network %s is stuck mid-reload and requires manual intervention", name),
+			)
+		}
+		if envelope.State != cache.PodIfaceNetworkPreparationPending {
+			return nil
+		}
+
+		if !req.IsEmpty() {
+			if err := c.SetAttachmentRequest(name, req); err != nil {
+				return err
+			}
+		}
+
+		if perr := c.plug([]podNIC{nic}, discoverFunc, configFunc); perr != nil {
+			return perr
+		}
+		if c.podIfaceNameByNetwork == nil {
+			c.podIfaceNameByNetwork = map[string]string{}
+		}
+		c.podIfaceNameByNetwork[name] = req.Ifname
+		return nil
+	})
+}
+
+// DisconnectNetwork detaches a single named network from the pod, outside of the UnplugNetworks
+// spec-reconciliation flow. Like ConnectNetwork, it is meant for imperative hotplug callers operating
+// on one network at a time.
+func (c *ConfigState) DisconnectNetwork(name string, cleanupFunc func(networkName string, launcherPid int) error) error {
+	return c.ns.Do(func() error {
+		envelope, err := c.readEnvelope(name)
+		if err != nil {
+			return err
+		}
+		if envelope.State == cache.PodIfaceNetworkPreparationPending {
+			return nil
+		}
+
+		if cerr := cleanupFunc(name, c.launcherPid); cerr != nil {
+			return fmt.Errorf("failed to clean up network %s: %w", name, cerr)
+		}
+		if derr := c.cache.Delete(name); derr != nil {
+			return derr
+		}
+		delete(c.podIfaceNameByNetwork, name)
+		return nil
+	})
+}
@@ -0,0 +1,40 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2023 Red Hat, Inc.
+ *
+ */
+
+package network
+
+import (
+	"fmt"
+)
+
+// MigrateCache eagerly upgrades the on-disk cache envelope of every network in networkNames to
+// cache.SchemaVersionCurrent, via the same readEnvelope path Run/Reload/ConnectNetwork already use to
+// migrate lazily on every read. Calling it for a launcher PID's known networks before the first Run is
+// not required for correctness, since a stale envelope is migrated the first time anything reads it
+// regardless, but it surfaces a migration failure up front rather than on the next incidental read.
+func (c *ConfigState) MigrateCache(networkNames []string) error {
+	return c.ns.Do(func() error {
+		for _, name := range networkNames {
+			if _, err := c.readEnvelope(name); err != nil {
+				return fmt.Errorf("failed to migrate cache envelope for %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+}